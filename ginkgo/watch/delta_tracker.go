@@ -0,0 +1,204 @@
+package watch
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/onsi/ginkgo/ginkgo/internal"
+)
+
+// WatchStrategy selects how DeltaTracker discovers the set of directories a
+// suite depends on (and therefore needs to watch for changes).
+type WatchStrategy string
+
+const (
+	// WatchStrategyDeps resolves dependencies via `go list -deps -json`,
+	// which understands the real import graph - including vendored
+	// dependencies, generated files, and cgo sources - rather than a
+	// textual scan. This is the default.
+	WatchStrategyDeps WatchStrategy = "deps"
+	// WatchStrategyRegex keeps the original depth-limited, regex-filtered
+	// source walk for backward compatibility with environments where `go
+	// list` isn't available or affordable (e.g. huge monorepos without
+	// module support, or sandboxes with no network/module cache).
+	WatchStrategyRegex WatchStrategy = "regex"
+)
+
+// watchedSuite pairs a discovered test suite with the directories
+// DeltaTracker is watching on its behalf.
+type watchedSuite struct {
+	Suite internal.TestSuite
+	dirs  []string
+}
+
+func (s *watchedSuite) Description() string {
+	return s.Suite.Description()
+}
+
+// Delta is the result of a single DeltaTracker.Delta call: which suites are
+// new since the tracker last saw them, which existing suites have changed
+// dependencies, and which packages triggered those changes.
+type Delta struct {
+	NewSuites        []*watchedSuite
+	ModifiedPackages []string
+
+	modifiedSuites []*watchedSuite
+}
+
+// ModifiedSuites returns the suites (already known to the tracker) whose
+// watched packages changed since the last Delta call.
+func (d Delta) ModifiedSuites() []*watchedSuite {
+	return d.modifiedSuites
+}
+
+// DeltaTracker watches a set of test suites - and, depending on strategy,
+// their transitive dependencies - for changes between calls to Delta.
+type DeltaTracker struct {
+	maxDepth     int
+	watchRegExp  *regexp.Regexp
+	strategy     WatchStrategy
+	includeGlobs []string
+	excludeGlobs []string
+
+	suites map[string]*watchedSuite // keyed by suite.Path
+	hashes *packageHashes
+}
+
+// NewDeltaTracker constructs a DeltaTracker using the default dependency
+// strategy (WatchStrategyDeps). maxDepth and watchRegExp are only consulted
+// by the regex walk, which that strategy falls back to for any suite `go
+// list` can't resolve.
+func NewDeltaTracker(maxDepth int, watchRegExp *regexp.Regexp) *DeltaTracker {
+	return NewDeltaTrackerWithStrategy(maxDepth, watchRegExp, WatchStrategyDeps, nil, nil)
+}
+
+// NewDeltaTrackerWithStrategy constructs a DeltaTracker that resolves a
+// suite's watched directories using the given strategy. --watch-strategy=
+// regex selects the legacy depth-limited textual import walk; the default,
+// deps, resolves the real import graph via `go list -deps -json` and falls
+// back to the regex walk for any suite that `go list` can't resolve (e.g. it
+// isn't part of a module) so a single bad package doesn't stop the watch.
+// includeGlobs and excludeGlobs are only consulted by the deps strategy, to
+// pull in (or prune out) dependencies outside the root package's own module.
+func NewDeltaTrackerWithStrategy(maxDepth int, watchRegExp *regexp.Regexp, strategy WatchStrategy, includeGlobs, excludeGlobs []string) *DeltaTracker {
+	return &DeltaTracker{
+		maxDepth:     maxDepth,
+		watchRegExp:  watchRegExp,
+		strategy:     strategy,
+		includeGlobs: includeGlobs,
+		excludeGlobs: excludeGlobs,
+		suites:       map[string]*watchedSuite{},
+		hashes:       newPackageHashes(),
+	}
+}
+
+// Delta reports which of suites are new to the tracker and which previously
+// seen suites have changes in their watched packages. Any suite whose
+// dependencies couldn't be resolved is returned in the errors map, keyed by
+// the suite itself, and is otherwise skipped for this call.
+func (d *DeltaTracker) Delta(suites internal.TestSuites) (Delta, map[internal.TestSuite]error) {
+	errors := map[internal.TestSuite]error{}
+	delta := Delta{}
+
+	// Each directory is hashed at most once per call, so suites that share a
+	// dependency all see its change rather than only the first one checked.
+	changedDirs := map[string]bool{}
+	dirChanged := func(dir string) bool {
+		changed, checked := changedDirs[dir]
+		if !checked {
+			changed = d.hashes.Changed(dir)
+			changedDirs[dir] = changed
+		}
+		return changed
+	}
+	newSuites := map[string]bool{}
+
+	for _, suite := range suites {
+		if _, ok := d.suites[suite.Path]; ok {
+			continue
+		}
+
+		dirs, err := d.resolveDirs(suite)
+		if err != nil {
+			errors[suite] = err
+			continue
+		}
+
+		ws := &watchedSuite{Suite: suite, dirs: dirs}
+		for _, dir := range dirs {
+			dirChanged(dir) // seed the baseline hash; a brand new suite isn't "modified"
+		}
+		d.suites[suite.Path] = ws
+		newSuites[suite.Path] = true
+		delta.NewSuites = append(delta.NewSuites, ws)
+	}
+
+	modifiedPackages := map[string]bool{}
+	for path, ws := range d.suites {
+		if newSuites[path] {
+			continue
+		}
+		changed := false
+		for _, dir := range ws.dirs {
+			if dirChanged(dir) {
+				changed = true
+				modifiedPackages[dir] = true
+			}
+		}
+		if changed {
+			delta.modifiedSuites = append(delta.modifiedSuites, ws)
+		}
+	}
+
+	delta.ModifiedPackages = make([]string, 0, len(modifiedPackages))
+	for pkg := range modifiedPackages {
+		delta.ModifiedPackages = append(delta.ModifiedPackages, pkg)
+	}
+	sort.Strings(delta.ModifiedPackages)
+
+	return delta, errors
+}
+
+// WatchedDirs returns the union of every directory DeltaTracker has resolved
+// for the suites it knows about, honoring whichever --watch-strategy and
+// --watch-include/--watch-exclude globs were configured. The event-driven
+// fsnotify watcher uses this - instead of recomputing its own depth-limited
+// regex walk - so that what triggers an fsnotify event and what Delta
+// considers "changed" always agree.
+func (d *DeltaTracker) WatchedDirs() []string {
+	seen := map[string]bool{}
+	var dirs []string
+	for _, ws := range d.suites {
+		for _, dir := range ws.dirs {
+			if seen[dir] {
+				continue
+			}
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// WillRun is called right before a suite is compiled and run, giving the
+// tracker a hook to acknowledge the change is being acted on. The hash
+// baseline is already updated as part of Delta, so this is currently a
+// no-op, but keeping the call in the watch loop lets the tracker grow
+// per-suite bookkeeping (e.g. run counts) without another signature change.
+func (d *DeltaTracker) WillRun(suite internal.TestSuite) {}
+
+func (d *DeltaTracker) resolveDirs(suite internal.TestSuite) ([]string, error) {
+	if d.strategy == WatchStrategyRegex {
+		return dependencyDirs(suite.Path, d.maxDepth, d.watchRegExp), nil
+	}
+
+	graph, err := newDependencyGraph(suite.Path)
+	if err != nil {
+		fmt.Printf("Could not resolve dependencies for %s via `go list -deps -json` (%s); falling back to the regex-based walk for this suite.\n", suite.PackageName, err)
+		return dependencyDirs(suite.Path, d.maxDepth, d.watchRegExp), nil
+	}
+
+	return graph.Dirs(suite.Path, d.includeGlobs, d.excludeGlobs), nil
+}
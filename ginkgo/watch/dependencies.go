@@ -0,0 +1,11 @@
+package watch
+
+import "go/build"
+
+// importPackage resolves an import path (or directory) to its *build.Package,
+// using the process's working directory as the base for relative imports.
+// It's a thin wrapper so the rest of the package doesn't need to know how
+// package resolution is configured.
+func importPackage(path string) (*build.Package, error) {
+	return build.Import(path, ".", 0)
+}
@@ -0,0 +1,125 @@
+package watch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+)
+
+// goListPackage is the subset of `go list -json` output dependencyGraph
+// needs: the package's own directory and the import paths of its direct
+// imports (go list reports only direct imports here, even with -deps).
+type goListPackage struct {
+	ImportPath string
+	Dir        string
+	Imports    []string
+	Module     *struct {
+		Path string
+		Dir  string
+	}
+	Standard bool
+}
+
+// dependencyGraph maps an import path to the directories of every package it
+// transitively depends on, built from a single `go list -deps -json`
+// invocation rather than the depth-limited regex walk the --watch-strategy=
+// regex path uses. This avoids both false negatives (missed vendored deps,
+// generated files, cgo sources the regex walk doesn't follow) and false
+// positives (textual import matches that aren't real dependencies).
+type dependencyGraph struct {
+	packages map[string]goListPackage // import path -> package
+}
+
+// newDependencyGraph runs `go list -deps -json` against pkgPath and parses
+// the resulting stream of JSON objects (one per package) into a graph.
+func newDependencyGraph(pkgPath string) (*dependencyGraph, error) {
+	cmd := exec.Command("go", "list", "-deps", "-json", pkgPath)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go list -deps -json %s failed: %w: %s", pkgPath, err, stderr.String())
+	}
+
+	packages := map[string]goListPackage{}
+	dec := json.NewDecoder(&stdout)
+	for dec.More() {
+		var pkg goListPackage
+		if err := dec.Decode(&pkg); err != nil {
+			return nil, fmt.Errorf("could not parse go list output for %s: %w", pkgPath, err)
+		}
+		packages[pkg.ImportPath] = pkg
+	}
+
+	return &dependencyGraph{packages: packages}, nil
+}
+
+// Dirs returns the directories of pkgPath and of every package in its
+// transitive dependency closure (the full set `go list -deps` resolved, not
+// just pkgPath's direct imports), excluding standard library packages (which
+// ginkgo watch has no business watching) and filtered by the optional
+// include/exclude glob patterns: a dependency outside pkgPath's own module is
+// only included if it matches includeGlobs.
+func (g *dependencyGraph) Dirs(pkgPath string, includeGlobs, excludeGlobs []string) []string {
+	root := g.resolve(pkgPath)
+	if root == nil {
+		return nil
+	}
+
+	moduleBoundary := ""
+	if root.Module != nil {
+		moduleBoundary = root.Module.Path
+	}
+
+	var dirs []string
+	for imp, pkg := range g.packages {
+		if pkg.Standard || pkg.Dir == "" {
+			continue
+		}
+		if moduleBoundary != "" && (pkg.Module == nil || pkg.Module.Path != moduleBoundary) {
+			if !matchesAny(imp, includeGlobs) {
+				continue
+			}
+		}
+		if matchesAny(imp, excludeGlobs) {
+			continue
+		}
+		dirs = append(dirs, pkg.Dir)
+	}
+	return dirs
+}
+
+// resolve looks up a package by import path, falling back to a scan by
+// directory: pkgPath is often a suite's filesystem directory rather than its
+// import path, and go list -json keys goListPackage.ImportPath, not Dir. A
+// relative directory is made absolute first, since go list reports Dir as an
+// absolute path.
+func (g *dependencyGraph) resolve(pkgPath string) *goListPackage {
+	if pkg, ok := g.packages[pkgPath]; ok {
+		return &pkg
+	}
+	absPath, err := filepath.Abs(pkgPath)
+	if err != nil {
+		absPath = pkgPath
+	}
+	for _, pkg := range g.packages {
+		pkg := pkg
+		if pkg.Dir == absPath {
+			return &pkg
+		}
+	}
+	return nil
+}
+
+func matchesAny(s string, globs []string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(glob, s); ok {
+			return true
+		}
+	}
+	return false
+}
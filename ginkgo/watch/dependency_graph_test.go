@@ -0,0 +1,96 @@
+package watch
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestDependencyGraphDirs(t *testing.T) {
+	graph := &dependencyGraph{
+		packages: map[string]goListPackage{
+			"example.com/app/foo": {
+				ImportPath: "example.com/app/foo",
+				Dir:        "/src/app/foo",
+				Imports:    []string{"example.com/app/bar"},
+				Module:     &struct{ Path, Dir string }{Path: "example.com/app", Dir: "/src/app"},
+			},
+			"example.com/app/bar": {
+				ImportPath: "example.com/app/bar",
+				Dir:        "/src/app/bar",
+				Imports:    []string{"example.com/vendored/baz"},
+				Module:     &struct{ Path, Dir string }{Path: "example.com/app", Dir: "/src/app"},
+			},
+			"example.com/vendored/baz": {
+				ImportPath: "example.com/vendored/baz",
+				Dir:        "/src/vendored/baz",
+				Module:     &struct{ Path, Dir string }{Path: "example.com/vendored", Dir: "/src/vendored"},
+			},
+			"fmt": {
+				ImportPath: "fmt",
+				Dir:        "/usr/lib/go/src/fmt",
+				Standard:   true,
+			},
+		},
+	}
+
+	t.Run("resolves by import path and returns the full transitive closure, excluding stdlib", func(t *testing.T) {
+		dirs := graph.Dirs("example.com/app/foo", nil, nil)
+		sort.Strings(dirs)
+		want := []string{"/src/app/bar", "/src/app/foo"}
+		if !stringSlicesEqual(dirs, want) {
+			t.Fatalf("got %v, want %v", dirs, want)
+		}
+	})
+
+	t.Run("resolves by directory when pkgPath isn't a known import path", func(t *testing.T) {
+		dirs := graph.Dirs("/src/app/foo", nil, nil)
+		sort.Strings(dirs)
+		want := []string{"/src/app/bar", "/src/app/foo"}
+		if !stringSlicesEqual(dirs, want) {
+			t.Fatalf("got %v, want %v", dirs, want)
+		}
+	})
+
+	t.Run("includes an out-of-module dependency matching includeGlobs", func(t *testing.T) {
+		dirs := graph.Dirs("example.com/app/foo", []string{"example.com/vendored/*"}, nil)
+		sort.Strings(dirs)
+		want := []string{"/src/app/bar", "/src/app/foo", "/src/vendored/baz"}
+		if !stringSlicesEqual(dirs, want) {
+			t.Fatalf("got %v, want %v", dirs, want)
+		}
+	})
+
+	t.Run("excludeGlobs prunes even an in-module dependency", func(t *testing.T) {
+		dirs := graph.Dirs("example.com/app/foo", nil, []string{"example.com/app/bar"})
+		sort.Strings(dirs)
+		want := []string{"/src/app/foo"}
+		if !stringSlicesEqual(dirs, want) {
+			t.Fatalf("got %v, want %v", dirs, want)
+		}
+	})
+
+	t.Run("unknown pkgPath returns nil", func(t *testing.T) {
+		if dirs := graph.Dirs("example.com/nope", nil, nil); dirs != nil {
+			t.Fatalf("got %v, want nil", dirs)
+		}
+	})
+}
+
+func TestMatchesAny(t *testing.T) {
+	cases := []struct {
+		s     string
+		globs []string
+		want  bool
+	}{
+		{"example.com/app/foo", nil, false},
+		{"example.com/app/foo", []string{"example.com/app/*"}, true},
+		{"example.com/app/foo", []string{"example.com/other/*"}, false},
+		{"example.com/app/foo", []string{"nope", "example.com/app/*"}, true},
+	}
+
+	for _, c := range cases {
+		if got := matchesAny(c.s, c.globs); got != c.want {
+			t.Errorf("matchesAny(%q, %v) = %v, want %v", c.s, c.globs, got, c.want)
+		}
+	}
+}
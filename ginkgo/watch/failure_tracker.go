@@ -0,0 +1,118 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/onsi/ginkgo/types"
+)
+
+// jsonSpecReport is the subset of ginkgo's JSON report schema failureTracker
+// needs to identify which specs failed and where they live on disk, so that
+// they can be re-focused on the next watch iteration via --focus-file.
+type jsonSpecReport struct {
+	State            string `json:"State"`
+	LeafNodeLocation struct {
+		FileName   string `json:"FileName"`
+		LineNumber int    `json:"LineNumber"`
+	} `json:"LeafNodeLocation"`
+}
+
+type jsonSuiteReport struct {
+	SpecReports []jsonSpecReport `json:"SpecReports"`
+}
+
+// nonPassingStates are the spec states RecordResult treats as failures when
+// deciding what to focus on next. This is every terminal state other than
+// passed/skipped/pending - a panic, timeout, or interrupt is just as much a
+// reason to re-run a spec as an assertion failure.
+var nonPassingStates = map[string]bool{
+	"failed":      true,
+	"panicked":    true,
+	"timedout":    true,
+	"aborted":     true,
+	"interrupted": true,
+}
+
+// failureTracker remembers, per package, the focus-file filters for specs
+// that failed on their last run. WatchSpecs consults it to narrow the next
+// run down to just those specs, and clears an entry once everything it was
+// tracking for that package passes - at which point the caller should go
+// back to running the package's full suite.
+type failureTracker struct {
+	focusFilesByPackage map[string][]string
+}
+
+func newFailureTracker() *failureTracker {
+	return &failureTracker{focusFilesByPackage: map[string][]string{}}
+}
+
+// FocusFilesFor returns the --focus-file filters (if any) that should be
+// applied when re-running pkg because of previously-failing specs.
+func (ft *failureTracker) FocusFilesFor(pkg string) []string {
+	return ft.focusFilesByPackage[pkg]
+}
+
+// RecordResult inspects the JSON report just written for a suite and updates
+// the tracker: if the run passed, any narrowing for that package is cleared
+// so the next change runs the full suite again; if it failed, the tracker
+// narrows to (only) the specs that are still failing.
+func (ft *failureTracker) RecordResult(pkg string, jsonReportPath string, passed bool) error {
+	if passed {
+		delete(ft.focusFilesByPackage, pkg)
+		return nil
+	}
+
+	focusFiles, err := failingSpecLocations(jsonReportPath)
+	if err != nil {
+		return err
+	}
+
+	if len(focusFiles) == 0 {
+		// The suite failed (e.g. a compilation error) but we couldn't
+		// attribute it to specific specs - don't narrow, just run everything
+		// next time.
+		delete(ft.focusFilesByPackage, pkg)
+		return nil
+	}
+
+	ft.focusFilesByPackage[pkg] = focusFiles
+	return nil
+}
+
+// failingSpecLocations reads a JSON report and returns "file:line" for every
+// spec in a non-passing state. It's shared by failureTracker.RecordResult
+// (for --focus-file narrowing) and the --watch-notify wiring in
+// watch_command.go (so a notification's failure list isn't limited to
+// --watch-failed-only runs).
+func failingSpecLocations(jsonReportPath string) ([]string, error) {
+	data, err := os.ReadFile(jsonReportPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read JSON report to find failures: %w", err)
+	}
+
+	var suiteReports []jsonSuiteReport
+	if err := json.Unmarshal(data, &suiteReports); err != nil {
+		return nil, fmt.Errorf("could not parse JSON report to find failures: %w", err)
+	}
+
+	var locations []string
+	for _, suiteReport := range suiteReports {
+		for _, spec := range suiteReport.SpecReports {
+			if !nonPassingStates[spec.State] {
+				continue
+			}
+			locations = append(locations, fmt.Sprintf("%s:%d", spec.LeafNodeLocation.FileName, spec.LeafNodeLocation.LineNumber))
+		}
+	}
+	return locations, nil
+}
+
+// withFocusFiles returns a copy of suiteConfig with FocusFiles set to
+// focusFiles, suitable for passing to internal.RunCompiledSuite without
+// mutating the watcher's shared configuration.
+func withFocusFiles(suiteConfig types.SuiteConfig, focusFiles []string) types.SuiteConfig {
+	suiteConfig.FocusFiles = focusFiles
+	return suiteConfig
+}
@@ -0,0 +1,75 @@
+package watch
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeJSONReport(t *testing.T, states ...string) string {
+	t.Helper()
+
+	var specReports []jsonSpecReport
+	for i, state := range states {
+		spec := jsonSpecReport{State: state}
+		spec.LeafNodeLocation.FileName = "spec_test.go"
+		spec.LeafNodeLocation.LineNumber = i + 1
+		specReports = append(specReports, spec)
+	}
+
+	data, err := json.Marshal([]jsonSuiteReport{{SpecReports: specReports}})
+	if err != nil {
+		t.Fatalf("could not marshal fixture report: %s", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("could not write fixture report: %s", err)
+	}
+	return path
+}
+
+func TestFailureTrackerRecordResult(t *testing.T) {
+	t.Run("a passing run clears any existing narrowing", func(t *testing.T) {
+		ft := newFailureTracker()
+		ft.focusFilesByPackage["pkg"] = []string{"spec_test.go:1"}
+
+		if err := ft.RecordResult("pkg", writeJSONReport(t, "passed"), true); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got := ft.FocusFilesFor("pkg"); got != nil {
+			t.Fatalf("got %v, want nil", got)
+		}
+	})
+
+	for _, state := range []string{"failed", "panicked", "timedout", "aborted", "interrupted"} {
+		state := state
+		t.Run("narrows to specs in state "+state, func(t *testing.T) {
+			ft := newFailureTracker()
+			path := writeJSONReport(t, "passed", state)
+
+			if err := ft.RecordResult("pkg", path, false); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			want := []string{"spec_test.go:2"}
+			if got := ft.FocusFilesFor("pkg"); !stringSlicesEqual(got, want) {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("a failure with no attributable specs clears narrowing instead of focusing on nothing", func(t *testing.T) {
+		ft := newFailureTracker()
+		ft.focusFilesByPackage["pkg"] = []string{"spec_test.go:1"}
+		path := writeJSONReport(t, "passed", "skipped", "pending")
+
+		if err := ft.RecordResult("pkg", path, false); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got := ft.FocusFilesFor("pkg"); got != nil {
+			t.Fatalf("got %v, want nil", got)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fsnotifyWatcher watches a set of suite packages (and their dependencies)
+// for changes using the OS's native file-event API, coalescing bursts of
+// events into a single signal via a debounce window.
+type fsnotifyWatcher struct {
+	watcher  *fsnotify.Watcher
+	debounce time.Duration
+	watched  map[string]bool
+}
+
+// newFsnotifyWatcher constructs an fsnotifyWatcher, registering a recursive
+// watch on every directory in dirs. dirs should come from the same
+// resolution DeltaTracker uses (DeltaTracker.WatchedDirs) so that the
+// event-driven watcher and Delta always agree on what counts as "watched" -
+// regardless of --watch-strategy or --watch-include/--watch-exclude.
+// It returns an error if the underlying platform has no fsnotify backend or
+// if establishing any of the watches fails (callers should fall back to
+// polling in either case); running out of watch descriptors (ENOSPC) gets its
+// own error message so it can be told apart in the log.
+func newFsnotifyWatcher(dirs []string, debounce time.Duration) (*fsnotifyWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	fw := &fsnotifyWatcher{
+		watcher:  w,
+		debounce: debounce,
+		watched:  map[string]bool{},
+	}
+
+	if err := fw.addDirs(dirs); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	return fw, nil
+}
+
+// addDirs registers a recursive watch on each of dirs.
+func (fw *fsnotifyWatcher) addDirs(dirs []string) error {
+	for _, dir := range dirs {
+		if err := fw.addDir(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fw *fsnotifyWatcher) addDir(dir string) error {
+	if fw.watched[dir] {
+		return nil
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), ".") && info.Name() != "." {
+			return filepath.SkipDir
+		}
+		if werr := fw.watcher.Add(path); werr != nil {
+			return werr
+		}
+		fw.watched[path] = true
+		return nil
+	})
+
+	if errors.Is(err, syscall.ENOSPC) {
+		return fmt.Errorf("ran out of OS file watch descriptors (ENOSPC) while watching %s: %w", dir, err)
+	}
+
+	return err
+}
+
+// WaitForChange blocks until a filesystem event arrives that isn't coalesced
+// away by the debounce window, then returns. It returns an error if the
+// underlying watcher breaks (e.g. ENOSPC from a dependency added after
+// start-up), so the caller can fall back to polling.
+func (fw *fsnotifyWatcher) WaitForChange(interrupted <-chan struct{}) (bool, error) {
+	var debounceTimer *time.Timer
+	var debounceC <-chan time.Time
+
+	for {
+		select {
+		case event, ok := <-fw.watcher.Events:
+			if !ok {
+				return false, errors.New("fsnotify event channel closed")
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+			if debounceTimer == nil {
+				debounceTimer = time.NewTimer(fw.debounce)
+				debounceC = debounceTimer.C
+			} else {
+				if !debounceTimer.Stop() {
+					<-debounceTimer.C
+				}
+				debounceTimer.Reset(fw.debounce)
+			}
+		case err, ok := <-fw.watcher.Errors:
+			if !ok {
+				return false, errors.New("fsnotify error channel closed")
+			}
+			if errors.Is(err, syscall.ENOSPC) {
+				return false, err
+			}
+			// non-fatal errors (e.g. a watched file briefly disappearing) are
+			// ignored and the wait continues.
+		case <-debounceC:
+			return true, nil
+		case <-interrupted:
+			return false, nil
+		}
+	}
+}
+
+func (fw *fsnotifyWatcher) Close() error {
+	return fw.watcher.Close()
+}
+
+// dependencyDirs returns pkgPath plus the directories of every package it
+// transitively imports, up to depth hops, whose import path matches
+// watchRegexp. DeltaTracker uses this walk for --watch-strategy=regex, and
+// for any suite `go list` can't resolve under the default deps strategy.
+func dependencyDirs(pkgPath string, depth int, watchRegexp *regexp.Regexp) []string {
+	seen := map[string]bool{}
+	dirs := []string{}
+
+	var visit func(path string, remainingDepth int)
+	visit = func(path string, remainingDepth int) {
+		pkg, err := importPackage(path)
+		if err != nil {
+			return
+		}
+		if seen[pkg.Dir] {
+			return
+		}
+		seen[pkg.Dir] = true
+		dirs = append(dirs, pkg.Dir)
+
+		if remainingDepth == 0 {
+			return
+		}
+		for _, imp := range pkg.Imports {
+			if !watchRegexp.MatchString(imp) {
+				continue
+			}
+			visit(imp, remainingDepth-1)
+		}
+	}
+
+	visit(pkgPath, depth)
+	return dirs
+}
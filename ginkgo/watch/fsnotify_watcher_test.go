@@ -0,0 +1,102 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// newTestFsnotifyWatcher sets up an fsnotifyWatcher watching dir with the
+// given debounce window, skipping the test if the sandbox has no usable
+// fsnotify backend (e.g. no inotify).
+func newTestFsnotifyWatcher(t *testing.T, dir string, debounce time.Duration) *fsnotifyWatcher {
+	t.Helper()
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Skipf("fsnotify unavailable in this environment: %s", err)
+	}
+	if err := w.Add(dir); err != nil {
+		w.Close()
+		t.Skipf("could not watch %s in this environment: %s", dir, err)
+	}
+	t.Cleanup(func() { w.Close() })
+
+	return &fsnotifyWatcher{
+		watcher:  w,
+		debounce: debounce,
+		watched:  map[string]bool{dir: true},
+	}
+}
+
+func TestFsnotifyWatcherDebounceCoalescesBurstsOfEvents(t *testing.T) {
+	dir := t.TempDir()
+	debounce := 100 * time.Millisecond
+	fw := newTestFsnotifyWatcher(t, dir, debounce)
+
+	interrupted := make(chan struct{})
+	changed := make(chan bool, 1)
+	errs := make(chan error, 1)
+	go func() {
+		ok, err := fw.WaitForChange(interrupted)
+		if err != nil {
+			errs <- err
+			return
+		}
+		changed <- ok
+	}()
+
+	start := time.Now()
+	burstSpacing := debounce / 3
+	for i := 0; i < 4; i++ {
+		if i > 0 {
+			time.Sleep(burstSpacing)
+		}
+		path := filepath.Join(dir, "file.txt")
+		if err := os.WriteFile(path, []byte(time.Now().String()), 0644); err != nil {
+			t.Fatalf("could not write %s: %s", path, err)
+		}
+	}
+	burstEnd := time.Now()
+
+	select {
+	case err := <-errs:
+		t.Fatalf("unexpected error from WaitForChange: %s", err)
+	case ok := <-changed:
+		if !ok {
+			t.Fatalf("expected WaitForChange to report a change, got false")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("WaitForChange did not return within 2s of the burst ending")
+	}
+	elapsed := time.Since(start)
+
+	// If each event in the burst independently triggered its own debounce
+	// timer rather than resetting a shared one, WaitForChange would have
+	// returned ~debounce after the *first* write. Instead it should only
+	// return once the burst is done and the debounce window has elapsed
+	// since the *last* write - i.e. noticeably after the burst ended.
+	minElapsed := burstEnd.Sub(start) + debounce/2
+	if elapsed < minElapsed {
+		t.Fatalf("WaitForChange returned after %s, expected at least %s (events should reset the debounce timer instead of firing after the first one)", elapsed, minElapsed)
+	}
+}
+
+func TestFsnotifyWatcherWaitForChangeReturnsOnInterrupt(t *testing.T) {
+	dir := t.TempDir()
+	fw := newTestFsnotifyWatcher(t, dir, time.Second)
+
+	interrupted := make(chan struct{})
+	close(interrupted)
+
+	ok, err := fw.WaitForChange(interrupted)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if ok {
+		t.Fatalf("expected no change to be reported, got true")
+	}
+}
@@ -0,0 +1,244 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// runRequest is sent over watchServer.RunRequests() to ask WatchSpecs to
+// compile-and-run a suite out of band, in response to POST /run.
+type runRequest struct {
+	// Package names a single suite to run; an empty string means "run every
+	// watched suite", mirroring how a filesystem change with no narrower
+	// target runs everything that's modified.
+	Package string
+}
+
+// focusRequest carries the filters POST /focus asks WatchSpecs to apply to
+// every subsequent run, until the next POST /focus clears or replaces them.
+type focusRequest struct {
+	Focus []string
+	Skip  []string
+}
+
+// suiteStatus is the last known result for a single watched suite, as
+// reported by GET /status.
+type suiteStatus struct {
+	Description string    `json:"description"`
+	LastPassed  bool      `json:"lastPassed"`
+	LastRanAt   time.Time `json:"lastRanAt"`
+	LastSeed    int64     `json:"lastSeed"`
+}
+
+// watchServer is the optional HTTP control/status endpoint enabled via
+// --watch-http-addr. It gives editor plugins and CI dashboards a way to
+// inspect watch state and to drive it (force a run, change focus) without
+// scripting around stdout, mirroring how ginkgo's parallel-run server
+// exposes state over HTTP.
+type watchServer struct {
+	mu       sync.Mutex
+	suites   map[string]*suiteStatus
+	inFlight bool
+
+	reportDir string
+
+	runRequests   chan runRequest
+	focusRequests chan focusRequest
+
+	subscribersMu sync.Mutex
+	subscribers   map[chan watchEvent]bool
+
+	server *http.Server
+}
+
+func newWatchServer(reportDir string) *watchServer {
+	return &watchServer{
+		suites:        map[string]*suiteStatus{},
+		reportDir:     reportDir,
+		runRequests:   make(chan runRequest, 1),
+		focusRequests: make(chan focusRequest, 1),
+		subscribers:   map[chan watchEvent]bool{},
+	}
+}
+
+// Start begins serving on addr in the background. It returns once the
+// listener is bound so callers can rely on the server being reachable
+// immediately after Start returns.
+func (s *watchServer) Start(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/run", s.handleRun)
+	mux.HandleFunc("/focus", s.handleFocus)
+	mux.HandleFunc("/events", s.handleEvents)
+	mux.HandleFunc("/report/latest.json", s.handleLatestReport)
+
+	s.server = &http.Server{Addr: addr, Handler: mux}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	go s.server.Serve(ln)
+	fmt.Printf("Watch control server listening on %s\n", addr)
+	return nil
+}
+
+func (s *watchServer) Stop() {
+	if s.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.server.Shutdown(ctx)
+}
+
+// RunRequests is consumed by WatchSpecs' select loop to learn about
+// POST /run calls that should trigger an out-of-band run.
+func (s *watchServer) RunRequests() <-chan runRequest { return s.runRequests }
+
+// FocusRequests is consumed by WatchSpecs' select loop to learn about
+// POST /focus calls that should change the filters applied to future runs.
+func (s *watchServer) FocusRequests() <-chan focusRequest { return s.focusRequests }
+
+// SetInFlight records whether a run is currently executing, for /status.
+func (s *watchServer) SetInFlight(inFlight bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight = inFlight
+}
+
+// RecordSuiteResult updates /status with the outcome of the most recent run
+// of a suite.
+func (s *watchServer) RecordSuiteResult(pkg, description string, passed bool, seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.suites[pkg] = &suiteStatus{
+		Description: description,
+		LastPassed:  passed,
+		LastRanAt:   time.Now(),
+		LastSeed:    seed,
+	}
+}
+
+// Broadcast publishes evt to every client currently connected to
+// GET /events.
+func (s *watchServer) Broadcast(evt watchEvent) {
+	s.subscribersMu.Lock()
+	defer s.subscribersMu.Unlock()
+	for ch := range s.subscribers {
+		select {
+		case ch <- evt:
+		default:
+			// a slow subscriber shouldn't block the watch loop; they'll miss
+			// this event and catch up on the next one.
+		}
+	}
+}
+
+func (s *watchServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		InFlight bool                    `json:"inFlight"`
+		Suites   map[string]*suiteStatus `json:"suites"`
+	}{
+		InFlight: s.inFlight,
+		Suites:   s.suites,
+	})
+}
+
+func (s *watchServer) handleRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST required", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req runRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		req.Package = r.URL.Query().Get("package")
+	}
+
+	select {
+	case s.runRequests <- req:
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "a run is already queued", http.StatusTooManyRequests)
+	}
+}
+
+func (s *watchServer) handleFocus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST required", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req focusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case s.focusRequests <- req:
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "a focus change is already queued", http.StatusTooManyRequests)
+	}
+}
+
+func (s *watchServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := make(chan watchEvent, 8)
+	s.subscribersMu.Lock()
+	s.subscribers[ch] = true
+	s.subscribersMu.Unlock()
+	defer func() {
+		s.subscribersMu.Lock()
+		delete(s.subscribers, ch)
+		s.subscribersMu.Unlock()
+	}()
+
+	for {
+		select {
+		case evt := <-ch:
+			data, err := json.Marshal(evt)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *watchServer) handleLatestReport(w http.ResponseWriter, r *http.Request) {
+	if s.reportDir == "" {
+		http.Error(w, "no --watch-report-dir configured", http.StatusNotFound)
+		return
+	}
+	http.ServeFile(w, r, s.reportDir+"/latest.json")
+}
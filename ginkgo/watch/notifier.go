@@ -0,0 +1,158 @@
+package watch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// suiteNotification is the per-suite outcome notifiers are invoked with
+// as each suite in a watch iteration finishes.
+type suiteNotification struct {
+	Suite    string
+	Passed   bool
+	Duration time.Duration
+	Seed     int64
+	Failures []string
+}
+
+// notifier is invoked once per suite in every watch iteration, right after
+// that suite's run completes.
+// Implementations are expected to be best-effort: a notification failure is
+// logged but never interrupts the watch loop.
+type notifier interface {
+	Notify(n suiteNotification) error
+}
+
+// buildNotifiers constructs the notifier backends requested via
+// --watch-notify and --watch-on-pass/--watch-on-fail.
+func buildNotifiers(opts watchOptions) ([]notifier, error) {
+	var notifiers []notifier
+
+	switch {
+	case opts.NotifyScheme == "":
+		// no --watch-notify backend configured
+	case opts.NotifyScheme == "desktop":
+		notifiers = append(notifiers, desktopNotifier{})
+	case strings.HasPrefix(opts.NotifyScheme, "webhook:"):
+		url := strings.TrimPrefix(opts.NotifyScheme, "webhook:")
+		if url == "" {
+			return nil, fmt.Errorf("--watch-notify=webhook:<url> requires a URL")
+		}
+		notifiers = append(notifiers, webhookNotifier{url: url})
+	default:
+		return nil, fmt.Errorf("unknown --watch-notify scheme %q: must be desktop or webhook:<url>", opts.NotifyScheme)
+	}
+
+	if opts.OnPass != "" || opts.OnFail != "" {
+		notifiers = append(notifiers, shellNotifier{onPass: opts.OnPass, onFail: opts.OnFail})
+	}
+
+	return notifiers, nil
+}
+
+// notifyAll runs every configured notifier for n, printing (rather than
+// aborting on) any error so a broken notifier doesn't take down the watch
+// loop.
+func notifyAll(notifiers []notifier, n suiteNotification) {
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(n); err != nil {
+			fmt.Printf("watch notifier failed: %s\n", err)
+		}
+	}
+}
+
+// desktopNotifier shells out to the platform's native notification tool.
+// These are all fire-and-forget CLI calls; ginkgo doesn't depend on any
+// notification library to keep this optional feature's footprint small.
+type desktopNotifier struct{}
+
+func (desktopNotifier) Notify(n suiteNotification) error {
+	title := fmt.Sprintf("ginkgo watch: %s", n.Suite)
+	message := "PASSED"
+	if !n.Passed {
+		message = "FAILED"
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		return exec.Command("osascript", "-e", script).Run()
+	case "windows":
+		return exec.Command("msg", "*", fmt.Sprintf("%s: %s", title, message)).Run()
+	default:
+		return exec.Command("notify-send", title, message).Run()
+	}
+}
+
+// webhookNotifier POSTs a JSON body to an arbitrary URL, for users who want
+// to wire ginkgo watch into chatops, dashboards, or other CI-adjacent
+// tooling without shelling out.
+type webhookNotifier struct {
+	url string
+}
+
+func (w webhookNotifier) Notify(n suiteNotification) error {
+	body, err := json.Marshal(struct {
+		Suite    string   `json:"suite"`
+		Passed   bool     `json:"passed"`
+		Duration float64  `json:"duration"`
+		Seed     int64    `json:"seed"`
+		Failures []string `json:"failures"`
+	}{
+		Suite:    n.Suite,
+		Passed:   n.Passed,
+		Duration: n.Duration.Seconds(),
+		Seed:     n.Seed,
+		Failures: n.Failures,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", w.url, resp.Status)
+	}
+	return nil
+}
+
+// shellNotifier runs a user-provided command on pass/fail, with the run's
+// metadata available as environment variables - this is the escape hatch for
+// users who currently script around `ginkgo watch` output by tailing stdout.
+type shellNotifier struct {
+	onPass string
+	onFail string
+}
+
+func (s shellNotifier) Notify(n suiteNotification) error {
+	command := s.onPass
+	if !n.Passed {
+		command = s.onFail
+	}
+	if command == "" {
+		return nil
+	}
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"GINKGO_WATCH_SUITE="+n.Suite,
+		"GINKGO_WATCH_PASSED="+strconv.FormatBool(n.Passed),
+		"GINKGO_WATCH_DURATION="+n.Duration.String(),
+		"GINKGO_WATCH_SEED="+strconv.FormatInt(n.Seed, 10),
+		"GINKGO_WATCH_FAILURES="+strings.Join(n.Failures, ","),
+	)
+	return cmd.Run()
+}
@@ -0,0 +1,76 @@
+package watch
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// packageHash is a cheap fingerprint of a package directory's contents, used
+// to detect when a package (or one of its dependencies) has changed on disk
+// between watch iterations. It hashes file names together with their size
+// and modification time rather than file contents, which is enough to catch
+// edits/adds/removes without the cost of reading every file on every poll.
+type packageHash struct {
+	Dir  string
+	Hash string
+}
+
+func computePackageHash(dir string) (packageHash, error) {
+	h := md5.New()
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return packageHash{}, err
+	}
+
+	return packageHash{Dir: dir, Hash: fmt.Sprintf("%x", h.Sum(nil))}, nil
+}
+
+// packageHashes is a cache of the most recently observed packageHash for
+// every directory DeltaTracker has looked at, so repeated Delta calls only
+// need to re-hash directories and compare against what was seen last time.
+type packageHashes struct {
+	hashes map[string]string
+}
+
+func newPackageHashes() *packageHashes {
+	return &packageHashes{hashes: map[string]string{}}
+}
+
+// Changed reports whether dir's contents differ from the last time it was
+// observed, recording the current hash as the new baseline either way.
+func (p *packageHashes) Changed(dir string) bool {
+	ph, err := computePackageHash(dir)
+	if err != nil {
+		// A directory that can't be hashed (e.g. briefly removed by the
+		// editor during a save) is treated as unchanged for this pass; the
+		// next pass will pick it back up once it settles.
+		return false
+	}
+
+	last, ok := p.hashes[dir]
+	p.hashes[dir] = ph.Hash
+	return !ok || last != ph.Hash
+}
@@ -0,0 +1,158 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/ginkgo/internal"
+	"github.com/onsi/ginkgo/types"
+)
+
+// watchEvent is the machine-readable record written alongside the per-suite
+// JSON/JUnit reports for each watch iteration. It's intended for IDEs and
+// CI-side tooling that want to consume `ginkgo watch` as a data source,
+// rather than scraping stdout the way existing --junit-report/--output-dir
+// consumers do.
+type watchEvent struct {
+	Timestamp       time.Time `json:"timestamp"`
+	ChangedPackages []string  `json:"changedPackages"`
+	Suites          []string  `json:"suites"`
+	Passed          bool      `json:"passed"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	Seed            int64     `json:"seed"`
+}
+
+// reportWriter archives the reports produced by a single watch iteration
+// into --watch-report-dir, naming them by timestamp and suite so earlier
+// iterations aren't overwritten, and maintains a rolling "latest" copy of
+// each for tools that only care about the most recent run.
+type reportWriter struct {
+	dir       string
+	iteration uint64
+}
+
+func newReportWriter(dir string) *reportWriter {
+	return &reportWriter{dir: dir}
+}
+
+// WriteIteration renders a combined JSON/JUnit report for suites (via
+// internal.FinalizeProfilesAndReportsForSuites, reusing the same report
+// generation the non-watch run path uses) into files prefixed by iteration
+// under the report directory, then records a watchEvent summarizing the
+// iteration.
+//
+// This is the only Finalize call WriteIteration makes, and it targets its own
+// archive-only paths: runIteration already calls Finalize once for the
+// user's own --json-report/--junit-report (if configured), and calling it
+// again per suite here - as an earlier version of this method did - meant
+// profile merging/cleanup ran multiple times over the same artifacts.
+func (rw *reportWriter) WriteIteration(suites internal.TestSuites, changedPackages []string, passed bool, duration time.Duration, seed int64, cliConfig types.CLIConfig, suiteConfig types.SuiteConfig, reporterConfig types.ReporterConfig, goFlagsConfig types.GoFlagsConfig) ([]string, error) {
+	if err := os.MkdirAll(rw.dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create --watch-report-dir %s: %w", rw.dir, err)
+	}
+
+	suiteNames := make([]string, 0, len(suites))
+	for _, suite := range suites {
+		suiteNames = append(suiteNames, suite.PackageName)
+	}
+	pkgTag := strings.Join(suiteNames, "+")
+	if pkgTag == "" {
+		pkgTag = "none"
+	}
+
+	// rw.iteration guarantees uniqueness even when two iterations land in the
+	// same wall-clock nanosecond (a real risk with UnixNano alone on fast,
+	// trivial suites); time.Now().UnixNano() keeps the filenames sortable and
+	// human-readable.
+	rw.iteration++
+	prefix := fmt.Sprintf("%d-%d-%s", time.Now().UnixNano(), rw.iteration, pkgTag)
+
+	jsonPath := filepath.Join(rw.dir, fmt.Sprintf("watch-report-%s.json", prefix))
+	junitPath := filepath.Join(rw.dir, fmt.Sprintf("watch-report-%s.xml", prefix))
+	reporterConfig.JSONReport = jsonPath
+	reporterConfig.JUnitReport = junitPath
+
+	messages, err := internal.FinalizeProfilesAndReportsForSuites(suites, cliConfig, suiteConfig, reporterConfig, goFlagsConfig)
+	if err != nil {
+		return messages, err
+	}
+
+	if err := rw.refreshLatest(jsonPath, "latest.json"); err != nil {
+		return messages, err
+	}
+	if err := rw.refreshLatest(junitPath, "latest.xml"); err != nil {
+		return messages, err
+	}
+
+	event := watchEvent{
+		Timestamp:       time.Now(),
+		ChangedPackages: changedPackages,
+		Suites:          suiteNames,
+		Passed:          passed,
+		DurationSeconds: duration.Seconds(),
+		Seed:            seed,
+	}
+	eventPath := filepath.Join(rw.dir, fmt.Sprintf("watch-event-%s.json", prefix))
+	if err := rw.writeJSON(eventPath, event); err != nil {
+		return messages, err
+	}
+	if err := rw.refreshLatest(eventPath, "latest-event.json"); err != nil {
+		return messages, err
+	}
+
+	return messages, nil
+}
+
+func (rw *reportWriter) writeJSON(path string, v interface{}) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// refreshLatest keeps a "latest" pointer to the most recent iteration's
+// report. We use a symlink on platforms that support it and fall back to a
+// plain copy on Windows, where symlink creation typically requires elevated
+// privileges.
+func (rw *reportWriter) refreshLatest(src, latestName string) error {
+	latest := filepath.Join(rw.dir, latestName)
+	os.Remove(latest)
+
+	if runtime.GOOS == "windows" {
+		return copyFile(src, latest)
+	}
+
+	relSrc, err := filepath.Rel(rw.dir, src)
+	if err != nil {
+		relSrc = src
+	}
+	return os.Symlink(relSrc, latest)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
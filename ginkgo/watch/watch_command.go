@@ -2,6 +2,7 @@ package watch
 
 import (
 	"fmt"
+	"os"
 	"regexp"
 	"time"
 
@@ -12,6 +13,10 @@ import (
 	"github.com/onsi/ginkgo/types"
 )
 
+// fallbackNotice is printed once when the event-driven watcher can't be used
+// and SpecWatcher drops back to polling.
+const fallbackNotice = "Falling back to polling: %s\n"
+
 func BuildWatchCommand() command.Command {
 	var suiteConfig = types.NewDefaultSuiteConfig()
 	var reporterConfig = types.NewDefaultReporterConfig()
@@ -29,19 +34,23 @@ func BuildWatchCommand() command.Command {
 		Flags:         flags,
 		Usage:         "ginkgo watch <FLAGS> <PACKAGES> -- <PASS-THROUGHS>",
 		ShortDoc:      "Watch the passed in <PACKAGES> and runs their tests whenever changes occur.",
-		Documentation: "Any arguments after -- will be passed to the test.",
+		Documentation: "Any arguments after -- will be passed to the test.  Use --watch-mode=poll to force the legacy polling loop (e.g. on NFS/overlayfs mounts) or --watch-mode=events to require the fsnotify-based watcher; the default, auto, prefers events and transparently falls back to polling.  Use --watch-report-dir=DIR to additionally archive a JSON/JUnit report and a machine-readable watch-event record for every iteration.  Use --watch-failed-only to, after any run with failures, re-run only the specs that failed until they pass, then automatically fall back to running the whole modified suite again.  Use --watch-http-addr=:PORT to expose a control/status server (GET /status, POST /run, POST /focus, GET /events, GET /report/latest.json) for editor plugins and CI dashboards.  Dependencies are discovered via `go list -deps -json` by default; pass --watch-strategy=regex to fall back to the legacy depth-limited, --watch-regexp-filtered source walk.  Dependencies outside the watched package's own module are excluded by default; pass --watch-include=GLOB (repeatable) to pull specific ones back in, or --watch-exclude=GLOB (repeatable) to prune further.  Use --watch-notify=desktop or --watch-notify=webhook:<url> to get a notification after every iteration, or --watch-on-pass=CMD/--watch-on-fail=CMD to run a shell command with the run's metadata in its environment.",
 		DocLink:       "watching-for-changes",
 		Command: func(args []string, additionalArgs []string) {
 			var errors []error
 			cliConfig, goFlagsConfig, errors = types.VetAndInitializeCLIAndGoConfig(cliConfig, goFlagsConfig)
 			command.AbortIfErrors("Ginkgo detected configuration issues:", errors)
 
+			watchOpts, args, err := parseWatchFlags(args)
+			command.AbortIfError("could not parse watch flags:", err)
+
 			watcher := &SpecWatcher{
 				cliConfig:      cliConfig,
 				goFlagsConfig:  goFlagsConfig,
 				suiteConfig:    suiteConfig,
 				reporterConfig: reporterConfig,
 				flags:          flags,
+				watchOpts:      watchOpts,
 
 				interruptHandler: interruptHandler,
 			}
@@ -52,11 +61,18 @@ func BuildWatchCommand() command.Command {
 }
 
 type SpecWatcher struct {
-	suiteConfig    types.SuiteConfig
-	reporterConfig types.ReporterConfig
-	cliConfig      types.CLIConfig
-	goFlagsConfig  types.GoFlagsConfig
-	flags          types.GinkgoFlagSet
+	suiteConfig        types.SuiteConfig
+	reporterConfig     types.ReporterConfig
+	cliConfig          types.CLIConfig
+	goFlagsConfig      types.GoFlagsConfig
+	flags              types.GinkgoFlagSet
+	watchOpts          watchOptions
+	failureTracker     *failureTracker
+	server             *watchServer
+	manualFocus        []string
+	manualSkip         []string
+	notifiers          []notifier
+	trackingScratchDir string
 
 	interruptHandler *interrupthandler.InterruptHandler
 }
@@ -69,7 +85,11 @@ func (w *SpecWatcher) WatchSpecs(args []string, additionalArgs []string) {
 	}
 
 	fmt.Printf("Identified %d test %s.  Locating dependencies to a depth of %d (this may take a while)...\n", len(suites), internal.PluralizedWord("suite", "suites", len(suites)), w.cliConfig.Depth)
-	deltaTracker := NewDeltaTracker(w.cliConfig.Depth, regexp.MustCompile(w.cliConfig.WatchRegExp))
+	deltaTracker := NewDeltaTrackerWithStrategy(w.cliConfig.Depth, regexp.MustCompile(w.cliConfig.WatchRegExp), w.watchOpts.Strategy, w.watchOpts.IncludeGlobs, w.watchOpts.ExcludeGlobs)
+	// set up before the initial run below so its failures are tracked too
+	if w.watchOpts.FailedOnly {
+		w.failureTracker = newFailureTracker()
+	}
 	delta, errors := deltaTracker.Delta(suites)
 
 	fmt.Printf("Watching %d %s:\n", len(delta.NewSuites), internal.PluralizedWord("suite", "suites", len(delta.NewSuites)))
@@ -86,11 +106,122 @@ func (w *SpecWatcher) WatchSpecs(args []string, additionalArgs []string) {
 		w.compileAndRun(suites[0], additionalArgs)
 	}
 
-	ticker := time.NewTicker(time.Second)
+	changed := w.startChangeSignal(deltaTracker)
+	defer changed.Close()
+
+	var reports *reportWriter
+	if w.watchOpts.ReportDir != "" {
+		reports = newReportWriter(w.watchOpts.ReportDir)
+	}
+
+	if w.watchOpts.HTTPAddr != "" {
+		w.server = newWatchServer(w.watchOpts.ReportDir)
+		err := w.server.Start(w.watchOpts.HTTPAddr)
+		command.AbortIfError("could not start --watch-http-addr server:", err)
+		defer w.server.Stop()
+	}
+
+	notifiers, err := buildNotifiers(w.watchOpts)
+	command.AbortIfError("could not configure --watch-notify:", err)
+	w.notifiers = notifiers
+	defer func() {
+		if w.trackingScratchDir != "" {
+			os.RemoveAll(w.trackingScratchDir)
+		}
+	}()
+
+	// runIteration compiles and runs candidateSuites, finalizes/archives
+	// their reports, and - when the HTTP control server is enabled - records
+	// their status and broadcasts a watch event. It's shared by filesystem
+	// change notifications and by the server's POST /run handler so both
+	// paths behave identically.
+	runIteration := func(candidateSuites internal.TestSuites, changedPackages []string) {
+		if len(candidateSuites) == 0 {
+			return
+		}
+
+		coloredStream := formatter.ColorableStdOut
+		if w.server != nil {
+			w.server.SetInFlight(true)
+			defer w.server.SetInFlight(false)
+		}
+
+		w.updateSeed()
+		w.computeSuccinctMode(len(candidateSuites))
+		passed := true
+		runStart := time.Now()
+		for _, suite := range candidateSuites {
+			if w.interruptHandler.WasInterrupted() {
+				return
+			}
+			deltaTracker.WillRun(suite)
+			suiteStart := time.Now()
+			suitePassed, failures := w.compileAndRun(suite, additionalArgs)
+			suiteDuration := time.Since(suiteStart)
+			passed = suitePassed && passed
+			if w.server != nil {
+				w.server.RecordSuiteResult(suite.PackageName, suite.Description(), suitePassed, w.suiteConfig.RandomSeed)
+			}
+			if len(w.notifiers) > 0 {
+				notifyAll(w.notifiers, suiteNotification{
+					Suite:    suite.PackageName,
+					Passed:   suitePassed,
+					Duration: suiteDuration,
+					Seed:     w.suiteConfig.RandomSeed,
+					Failures: failures,
+				})
+			}
+		}
+		if w.failureTracker != nil {
+			fmt.Fprintln(coloredStream, formatter.F("{{gray}}(--watch-failed-only: will re-run only previously-failing specs until they pass){{/}}"))
+		}
+		runDuration := time.Since(runStart)
+		color := "{{red}}"
+		if passed {
+			color = "{{green}}"
+		}
+		fmt.Fprintln(coloredStream, formatter.F(color+"\nDone.  Resuming watch...{{/}}"))
+
+		messages, err := internal.FinalizeProfilesAndReportsForSuites(candidateSuites, w.cliConfig, w.suiteConfig, w.reporterConfig, w.goFlagsConfig)
+		command.AbortIfError("could not finalize profiles:", err)
+		for _, message := range messages {
+			fmt.Println(message)
+		}
+
+		if reports != nil {
+			reportMessages, err := reports.WriteIteration(candidateSuites, changedPackages, passed, runDuration, w.suiteConfig.RandomSeed, w.cliConfig, w.suiteConfig, w.reporterConfig, w.goFlagsConfig)
+			command.AbortIfError("could not write watch reports:", err)
+			for _, message := range reportMessages {
+				fmt.Println(message)
+			}
+		}
+
+		if w.server != nil {
+			suiteNames := make([]string, 0, len(candidateSuites))
+			for _, suite := range candidateSuites {
+				suiteNames = append(suiteNames, suite.PackageName)
+			}
+			w.server.Broadcast(watchEvent{
+				Timestamp:       time.Now(),
+				ChangedPackages: changedPackages,
+				Suites:          suiteNames,
+				Passed:          passed,
+				DurationSeconds: runDuration.Seconds(),
+				Seed:            w.suiteConfig.RandomSeed,
+			})
+		}
+	}
 
 	for {
+		var serverRunRequests <-chan runRequest
+		var serverFocusRequests <-chan focusRequest
+		if w.server != nil {
+			serverRunRequests = w.server.RunRequests()
+			serverFocusRequests = w.server.FocusRequests()
+		}
+
 		select {
-		case <-ticker.C:
+		case <-changed.C():
 			suites, _ := internal.FindSuites(args, w.cliConfig, false)
 			delta, _ := deltaTracker.Delta(suites)
 			coloredStream := formatter.ColorableStdOut
@@ -119,49 +250,204 @@ func (w *SpecWatcher) WatchSpecs(args []string, additionalArgs []string) {
 				fmt.Fprintln(coloredStream, "")
 			}
 
-			if len(suites) == 0 {
+			runIteration(suites, delta.ModifiedPackages)
+		case req := <-serverRunRequests:
+			all, _ := internal.FindSuites(args, w.cliConfig, false)
+			target := internal.TestSuites{}
+			for _, suite := range all {
+				if req.Package == "" || suite.PackageName == req.Package {
+					target = append(target, suite)
+				}
+			}
+			if len(target) == 0 {
+				fmt.Printf("POST /run: no suite matching %q\n", req.Package)
 				break
 			}
+			fmt.Printf("POST /run: forcing a run of %d %s\n", len(target), internal.PluralizedWord("suite", "suites", len(target)))
+			runIteration(target, nil)
+		case req := <-serverFocusRequests:
+			w.manualFocus = req.Focus
+			w.manualSkip = req.Skip
+			fmt.Printf("POST /focus: focus=%v skip=%v\n", req.Focus, req.Skip)
+		case <-w.interruptHandler.InterruptChannel():
+			return
+		}
+	}
+}
 
-			w.updateSeed()
-			w.computeSuccinctMode(len(suites))
-			passed := true
-			for _, suite := range suites {
-				if w.interruptHandler.WasInterrupted() {
-					return
-				}
-				deltaTracker.WillRun(suite)
-				passed = w.compileAndRun(suite, additionalArgs) && passed
+// changeSignal notifies WatchSpecs whenever it should re-scan the suites for
+// changes, regardless of whether that notification came from the
+// event-driven fsnotify watcher or the legacy polling ticker.
+type changeSignal struct {
+	c      <-chan time.Time
+	fw     *fsnotifyWatcher
+	ticker *time.Ticker
+}
+
+func (c *changeSignal) C() <-chan time.Time { return c.c }
+
+func (c *changeSignal) Close() {
+	if c.fw != nil {
+		c.fw.Close()
+	}
+	if c.ticker != nil {
+		c.ticker.Stop()
+	}
+}
+
+// startChangeSignal sets up the event-driven fsnotify watcher when the
+// configured watch mode allows it, falling back to the original ticker-based
+// polling loop if fsnotify isn't available, if the platform lacks support, or
+// if the OS watch-descriptor limit is exhausted (ENOSPC).
+func (w *SpecWatcher) startChangeSignal(deltaTracker *DeltaTracker) *changeSignal {
+	pollInterval := w.watchOpts.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	startPolling := func() *changeSignal {
+		ticker := time.NewTicker(pollInterval)
+		return &changeSignal{c: ticker.C, ticker: ticker}
+	}
+
+	if w.watchOpts.Mode == WatchModePoll {
+		return startPolling()
+	}
+
+	fw, err := newFsnotifyWatcher(deltaTracker.WatchedDirs(), w.watchOpts.DebounceWindow)
+	if err != nil {
+		if w.watchOpts.Mode == WatchModeEvents {
+			command.AbortWith("could not start event-driven watcher: %s", err)
+		}
+		fmt.Printf(fallbackNotice, err)
+		return startPolling()
+	}
+
+	c := make(chan time.Time, 1)
+	go func() {
+		for {
+			changed, err := fw.WaitForChange(w.interruptHandler.InterruptChannel())
+			if err != nil {
+				fmt.Printf(fallbackNotice, err)
+				fw.Close()
+				forwardTicksUntilInterrupted(c, pollInterval, w.interruptHandler.InterruptChannel())
+				return
 			}
-			color := "{{red}}"
-			if passed {
-				color = "{{green}}"
+			if !changed {
+				return
 			}
-			fmt.Fprintln(coloredStream, formatter.F(color+"\nDone.  Resuming watch...{{/}}"))
+			select {
+			case c <- time.Now():
+			default:
+			}
+		}
+	}()
 
-			messages, err := internal.FinalizeProfilesAndReportsForSuites(suites, w.cliConfig, w.suiteConfig, w.reporterConfig, w.goFlagsConfig)
-			command.AbortIfError("could not finalize profiles:", err)
-			for _, message := range messages {
-				fmt.Println(message)
+	return &changeSignal{c: c, fw: fw}
+}
+
+// forwardTicksUntilInterrupted relays a polling ticker onto c until
+// interrupted fires, used when the event-driven watcher has broken mid-run
+// and the watch needs to keep going via polling rather than simply stopping.
+func forwardTicksUntilInterrupted(c chan<- time.Time, pollInterval time.Duration, interrupted <-chan struct{}) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case t := <-ticker.C:
+			select {
+			case c <- t:
+			default:
 			}
-		case <-w.interruptHandler.InterruptChannel():
+		case <-interrupted:
 			return
 		}
 	}
 }
 
-func (w *SpecWatcher) compileAndRun(suite internal.TestSuite, additionalArgs []string) bool {
+// compileAndRun compiles and runs suite, returning whether it passed and the
+// "file:line" locations of any non-passing specs. The latter is populated
+// whenever a JSON report is available for this run - not only when
+// --watch-failed-only is active - so --watch-notify/--watch-on-fail can
+// report what actually failed regardless of which other watch flags are set.
+func (w *SpecWatcher) compileAndRun(suite internal.TestSuite, additionalArgs []string) (bool, []string) {
 	suite = internal.CompileSuite(suite, w.goFlagsConfig)
 	if suite.CompilationError != nil {
 		fmt.Println(suite.CompilationError.Error())
-		return false
+		return false, nil
 	}
 	if w.interruptHandler.WasInterrupted() {
-		return false
+		return false, nil
 	}
-	suite = internal.RunCompiledSuite(suite, w.suiteConfig, w.reporterConfig, w.cliConfig, w.goFlagsConfig, additionalArgs)
+
+	suiteConfig := w.suiteConfig
+	if len(w.manualFocus) > 0 {
+		suiteConfig.FocusStrings = w.manualFocus
+	}
+	if len(w.manualSkip) > 0 {
+		suiteConfig.SkipStrings = w.manualSkip
+	}
+	if w.failureTracker != nil {
+		if focusFiles := w.failureTracker.FocusFilesFor(suite.PackageName); len(focusFiles) > 0 {
+			fmt.Printf("Re-running %d previously-failing %s in %s\n", len(focusFiles), internal.PluralizedWord("spec", "specs", len(focusFiles)), suite.PackageName)
+			suiteConfig = withFocusFiles(suiteConfig, focusFiles)
+		}
+	}
+
+	reporterConfig := w.reporterConfig
+	var reportPath string
+	if w.failureTracker != nil || len(w.notifiers) > 0 {
+		if w.reporterConfig.JSONReport != "" {
+			// The user already configured their own --json-report; reuse it
+			// instead of overwriting it with a private scratch path, so
+			// Finalize still produces the report they asked for.
+			reportPath = w.reporterConfig.JSONReport
+		} else {
+			var err error
+			reportPath, err = w.reportPathForTracking(suite)
+			if err == nil {
+				reporterConfig.JSONReport = reportPath
+			}
+		}
+	}
+
+	suite = internal.RunCompiledSuite(suite, suiteConfig, reporterConfig, w.cliConfig, w.goFlagsConfig, additionalArgs)
 	internal.Cleanup(w.goFlagsConfig, suite)
-	return suite.Passed
+
+	var failures []string
+	if reportPath != "" {
+		if locations, err := failingSpecLocations(reportPath); err == nil {
+			failures = locations
+		}
+	}
+
+	if w.failureTracker != nil && reportPath != "" {
+		if err := w.failureTracker.RecordResult(suite.PackageName, reportPath, suite.Passed); err != nil {
+			fmt.Println(err.Error())
+		}
+	}
+
+	return suite.Passed, failures
+}
+
+// reportPathForTracking returns a scratch path to write suite's JSON report
+// to purely so compileAndRun can inspect it afterwards, reusing
+// --watch-report-dir when the caller already maintains one and otherwise
+// lazily creating (and reusing) a single temp directory for the life of the
+// watch session.
+func (w *SpecWatcher) reportPathForTracking(suite internal.TestSuite) (string, error) {
+	dir := w.watchOpts.ReportDir
+	if dir == "" {
+		if w.trackingScratchDir == "" {
+			tmp, err := os.MkdirTemp("", "ginkgo-watch-tracking")
+			if err != nil {
+				return "", err
+			}
+			w.trackingScratchDir = tmp
+		}
+		dir = w.trackingScratchDir
+	}
+	return dir + string(os.PathSeparator) + suite.PackageName + "-tracking.json", nil
 }
 
 func (w *SpecWatcher) computeSuccinctMode(numSuites int) {
@@ -0,0 +1,117 @@
+package watch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WatchMode selects how SpecWatcher discovers filesystem changes.
+type WatchMode string
+
+const (
+	// WatchModeAuto uses event-driven watching when available and transparently
+	// falls back to polling when it isn't (e.g. unsupported platform, exhausted
+	// OS watch descriptors).
+	WatchModeAuto WatchMode = "auto"
+	// WatchModeEvents always uses the fsnotify-backed watcher and surfaces an
+	// error rather than falling back if it can't be set up.
+	WatchModeEvents WatchMode = "events"
+	// WatchModePoll always uses the legacy ticker-based polling loop. Useful on
+	// NFS/overlayfs mounts where inotify events aren't delivered reliably.
+	WatchModePoll WatchMode = "poll"
+)
+
+const (
+	defaultDebounceWindow = 500 * time.Millisecond
+	defaultPollInterval   = time.Second
+)
+
+// watchOptions holds the watch-command-specific settings that aren't part of
+// the shared suite/reporter/cli config. They're parsed out of the positional
+// arguments, wherever they appear among them, so that the watch command can grow its own flags
+// without reworking the shared GinkgoFlagSet.
+type watchOptions struct {
+	Mode           WatchMode
+	PollInterval   time.Duration
+	DebounceWindow time.Duration
+	ReportDir      string
+	FailedOnly     bool
+	HTTPAddr       string
+	Strategy       WatchStrategy
+	IncludeGlobs   []string
+	ExcludeGlobs   []string
+	NotifyScheme   string
+	OnPass         string
+	OnFail         string
+}
+
+func defaultWatchOptions() watchOptions {
+	return watchOptions{
+		Mode:           WatchModeAuto,
+		PollInterval:   defaultPollInterval,
+		DebounceWindow: defaultDebounceWindow,
+		Strategy:       WatchStrategyDeps,
+	}
+}
+
+// parseWatchFlags extracts every --watch-* flag that watchOptions holds from
+// args, returning the remaining arguments untouched so they can still be
+// handed to internal.FindSuites.
+func parseWatchFlags(args []string) (watchOptions, []string, error) {
+	opts := defaultWatchOptions()
+	remaining := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--watch-mode="):
+			mode := WatchMode(strings.TrimPrefix(arg, "--watch-mode="))
+			switch mode {
+			case WatchModeAuto, WatchModeEvents, WatchModePoll:
+				opts.Mode = mode
+			default:
+				return opts, nil, fmt.Errorf("unknown --watch-mode %q: must be one of auto, events, poll", mode)
+			}
+		case strings.HasPrefix(arg, "--watch-poll-interval="):
+			d, err := time.ParseDuration(strings.TrimPrefix(arg, "--watch-poll-interval="))
+			if err != nil {
+				return opts, nil, fmt.Errorf("invalid --watch-poll-interval: %w", err)
+			}
+			opts.PollInterval = d
+		case strings.HasPrefix(arg, "--watch-debounce="):
+			d, err := time.ParseDuration(strings.TrimPrefix(arg, "--watch-debounce="))
+			if err != nil {
+				return opts, nil, fmt.Errorf("invalid --watch-debounce: %w", err)
+			}
+			opts.DebounceWindow = d
+		case strings.HasPrefix(arg, "--watch-report-dir="):
+			opts.ReportDir = strings.TrimPrefix(arg, "--watch-report-dir=")
+		case arg == "--watch-failed-only":
+			opts.FailedOnly = true
+		case strings.HasPrefix(arg, "--watch-http-addr="):
+			opts.HTTPAddr = strings.TrimPrefix(arg, "--watch-http-addr=")
+		case strings.HasPrefix(arg, "--watch-strategy="):
+			strategy := WatchStrategy(strings.TrimPrefix(arg, "--watch-strategy="))
+			switch strategy {
+			case WatchStrategyDeps, WatchStrategyRegex:
+				opts.Strategy = strategy
+			default:
+				return opts, nil, fmt.Errorf("unknown --watch-strategy %q: must be one of deps, regex", strategy)
+			}
+		case strings.HasPrefix(arg, "--watch-include="):
+			opts.IncludeGlobs = append(opts.IncludeGlobs, strings.TrimPrefix(arg, "--watch-include="))
+		case strings.HasPrefix(arg, "--watch-exclude="):
+			opts.ExcludeGlobs = append(opts.ExcludeGlobs, strings.TrimPrefix(arg, "--watch-exclude="))
+		case strings.HasPrefix(arg, "--watch-notify="):
+			opts.NotifyScheme = strings.TrimPrefix(arg, "--watch-notify=")
+		case strings.HasPrefix(arg, "--watch-on-pass="):
+			opts.OnPass = strings.TrimPrefix(arg, "--watch-on-pass=")
+		case strings.HasPrefix(arg, "--watch-on-fail="):
+			opts.OnFail = strings.TrimPrefix(arg, "--watch-on-fail=")
+		default:
+			remaining = append(remaining, arg)
+		}
+	}
+
+	return opts, remaining, nil
+}
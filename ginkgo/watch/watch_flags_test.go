@@ -0,0 +1,135 @@
+package watch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWatchFlags(t *testing.T) {
+	cases := []struct {
+		name      string
+		args      []string
+		want      watchOptions
+		remaining []string
+		wantErr   bool
+	}{
+		{
+			name:      "defaults when nothing is set",
+			args:      []string{"./..."},
+			want:      defaultWatchOptions(),
+			remaining: []string{"./..."},
+		},
+		{
+			name: "parses every flag",
+			args: []string{
+				"--watch-mode=poll",
+				"--watch-poll-interval=2s",
+				"--watch-debounce=10ms",
+				"--watch-report-dir=/tmp/reports",
+				"--watch-failed-only",
+				"--watch-http-addr=:9000",
+				"--watch-strategy=regex",
+				"--watch-include=vendor/foo/...",
+				"--watch-exclude=vendor/bar/...",
+				"--watch-notify=desktop",
+				"--watch-on-pass=echo pass",
+				"--watch-on-fail=echo fail",
+				"./...",
+			},
+			want: watchOptions{
+				Mode:           WatchModePoll,
+				PollInterval:   2 * time.Second,
+				DebounceWindow: 10 * time.Millisecond,
+				ReportDir:      "/tmp/reports",
+				FailedOnly:     true,
+				HTTPAddr:       ":9000",
+				Strategy:       WatchStrategyRegex,
+				IncludeGlobs:   []string{"vendor/foo/..."},
+				ExcludeGlobs:   []string{"vendor/bar/..."},
+				NotifyScheme:   "desktop",
+				OnPass:         "echo pass",
+				OnFail:         "echo fail",
+			},
+			remaining: []string{"./..."},
+		},
+		{
+			name: "repeats --watch-include and --watch-exclude",
+			args: []string{"--watch-include=a", "--watch-include=b", "--watch-exclude=c"},
+			want: func() watchOptions {
+				opts := defaultWatchOptions()
+				opts.IncludeGlobs = []string{"a", "b"}
+				opts.ExcludeGlobs = []string{"c"}
+				return opts
+			}(),
+			remaining: []string{},
+		},
+		{
+			name:    "rejects an unknown --watch-mode",
+			args:    []string{"--watch-mode=bogus"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects an unknown --watch-strategy",
+			args:    []string{"--watch-strategy=bogus"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects an unparsable --watch-poll-interval",
+			args:    []string{"--watch-poll-interval=nope"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects an unparsable --watch-debounce",
+			args:    []string{"--watch-debounce=nope"},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, remaining, err := parseWatchFlags(c.args)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !watchOptionsEqual(got, c.want) {
+				t.Fatalf("got %+v, want %+v", got, c.want)
+			}
+			if !stringSlicesEqual(remaining, c.remaining) {
+				t.Fatalf("got remaining %v, want %v", remaining, c.remaining)
+			}
+		})
+	}
+}
+
+func watchOptionsEqual(a, b watchOptions) bool {
+	return a.Mode == b.Mode &&
+		a.PollInterval == b.PollInterval &&
+		a.DebounceWindow == b.DebounceWindow &&
+		a.ReportDir == b.ReportDir &&
+		a.FailedOnly == b.FailedOnly &&
+		a.HTTPAddr == b.HTTPAddr &&
+		a.Strategy == b.Strategy &&
+		stringSlicesEqual(a.IncludeGlobs, b.IncludeGlobs) &&
+		stringSlicesEqual(a.ExcludeGlobs, b.ExcludeGlobs) &&
+		a.NotifyScheme == b.NotifyScheme &&
+		a.OnPass == b.OnPass &&
+		a.OnFail == b.OnFail
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}